@@ -70,7 +70,7 @@ func getEnabledAuditors(conf config.KubeauditConfig) []string {
 func initAuditor(name string, conf config.KubeauditConfig) (kubeaudit.Auditable, error) {
 	switch name {
 	case apparmor.Name:
-		return apparmor.New(), nil
+		return apparmor.New(conf.GetAuditorConfigs().AppArmor), nil
 	case asat.Name:
 		return asat.New(), nil
 	case capabilities.Name:
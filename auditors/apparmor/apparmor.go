@@ -2,12 +2,14 @@ package apparmor
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/elliotxx/kubeaudit"
 	"github.com/elliotxx/kubeaudit/pkg/fix"
 	"github.com/elliotxx/kubeaudit/pkg/k8s"
 	"github.com/elliotxx/kubeaudit/pkg/override"
+	v1 "k8s.io/api/core/v1"
 )
 
 const Name = "apparmor"
@@ -22,6 +24,13 @@ const (
 	// AppArmorInvalidAnnotation occurs when the apparmor annotation key refers to a container which doesn't exist. This will
 	// prevent the manifest from being applied to a cluster with AppArmor enabled.
 	AppArmorInvalidAnnotation = "AppArmorInvalidAnnotation"
+	// AppArmorProfileConflict occurs when the legacy annotation and the typed securityContext.appArmorProfile
+	// field are both set but disagree on the profile to use.
+	AppArmorProfileConflict = "AppArmorProfileConflict"
+	// AppArmorDeprecatedAnnotation occurs when only the legacy annotation form is used on a manifest whose
+	// targeted Kubernetes version (see Config.MinKubeVersion) is new enough to support the typed
+	// securityContext.appArmorProfile field instead.
+	AppArmorDeprecatedAnnotation = "AppArmorDeprecatedAnnotation"
 )
 
 // As of Jan 14, 2020 these constants are not in the K8s API package, but once they are they should be replaced
@@ -40,11 +49,34 @@ const (
 
 const OverrideLabel = "allow-disabled-apparmor"
 
+// minTypedProfileKubeVersion is the Kubernetes version at which the typed securityContext.appArmorProfile
+// field became available, superseding the container.apparmor.security.beta.kubernetes.io/ annotation.
+const minTypedProfileKubeVersion = "1.30"
+
+// Config holds configuration options for the AppArmor auditor.
+type Config struct {
+	// MinKubeVersion is the Kubernetes version the audited manifests target, e.g. "1.30". When set to a version
+	// at or above minTypedProfileKubeVersion, containers which only use the legacy annotation raise
+	// AppArmorDeprecatedAnnotation instead of being considered compliant.
+	MinKubeVersion string
+	// IncludeEphemeralContainers causes the auditor to also audit the pod's ephemeral (kubectl debug)
+	// containers, which otherwise bypass this auditor entirely. Set conf.GetAuditorConfigs().AppArmor in the
+	// kubeaudit config file, or pass --include-ephemeral-containers on the CLI; auditors/all.Auditors passes
+	// this whole Config through to apparmor.New unmodified, so either path reaches the auditor.
+	IncludeEphemeralContainers bool
+	// GenerateProfileDir, when set, switches missing/disabled findings from fixes that merely point at
+	// runtime/default to fixes that generate a starter AppArmor profile under this directory and point the
+	// container at it instead. Gated behind an explicit opt-in so read-only audit runs are unaffected.
+	GenerateProfileDir string
+}
+
 // AppArmor implements Auditable
-type AppArmor struct{}
+type AppArmor struct {
+	config Config
+}
 
-func New() *AppArmor {
-	return &AppArmor{}
+func New(config Config) *AppArmor {
+	return &AppArmor{config: config}
 }
 
 // Audit checks that AppArmor is enabled for all containers
@@ -52,13 +84,21 @@ func (a *AppArmor) Audit(resource k8s.Resource, _ []k8s.Resource) ([]*kubeaudit.
 	var auditResults []*kubeaudit.AuditResult
 	var containerNames []string
 
-	for _, container := range k8s.GetContainers(resource) {
+	podProfile := getPodAppArmorProfile(resource)
+
+	containers := k8s.GetContainersWithOptions(resource, k8s.ContainerOptions{
+		IncludeEphemeralContainers: a.config.IncludeEphemeralContainers,
+	})
+
+	for _, container := range containers {
 		containerName := container.Name
 		containerNames = append(containerNames, containerName)
-		auditResult := auditContainer(container, resource)
-		auditResult = applyDisabledOverride(auditResult, containerName, resource)
-		if auditResult != nil {
-			auditResults = append(auditResults, auditResult)
+
+		for _, auditResult := range auditContainer(container, podProfile, resource, a.config) {
+			auditResult = applyDisabledOverride(auditResult, containerName, resource)
+			if auditResult != nil {
+				auditResults = append(auditResults, auditResult)
+			}
 		}
 	}
 
@@ -67,12 +107,37 @@ func (a *AppArmor) Audit(resource k8s.Resource, _ []k8s.Resource) ([]*kubeaudit.
 	return auditResults, nil
 }
 
-func auditContainer(container *k8s.ContainerV1, resource k8s.Resource) *kubeaudit.AuditResult {
+// auditContainer audits a single container, preferring the typed securityContext.appArmorProfile field (at the
+// container level, falling back to the pod level) over the legacy annotation, which is only audited on its own
+// when neither typed field is set. When a typed field is set, the annotation is only compared against it to
+// detect a conflict.
+func auditContainer(container *k8s.ContainerV1, podProfile *v1.AppArmorProfile, resource k8s.Resource, config Config) []*kubeaudit.AuditResult {
 	annotations := k8s.GetAnnotations(resource)
 	containerAnnotation := getContainerAnnotation(container)
 
+	typedProfile := getContainerAppArmorProfile(container)
+	podLevel := typedProfile == nil
+	if typedProfile == nil {
+		typedProfile = podProfile
+	}
+
+	if typedProfile != nil {
+		var results []*kubeaudit.AuditResult
+		if conflict := auditProfileConflict(container, typedProfile, containerAnnotation, annotations); conflict != nil {
+			results = append(results, conflict)
+		}
+		if result := auditTypedProfile(container, typedProfile, podLevel, config); result != nil {
+			results = append(results, result)
+		}
+		return results
+	}
+
+	if result := auditDeprecatedAnnotation(container, containerAnnotation, annotations, config); result != nil {
+		return []*kubeaudit.AuditResult{result}
+	}
+
 	if isAppArmorAnnotationMissing(containerAnnotation, annotations) {
-		return &kubeaudit.AuditResult{
+		return []*kubeaudit.AuditResult{{
 			Auditor:  Name,
 			Rule:     AppArmorAnnotationMissing,
 			Severity: kubeaudit.Error,
@@ -81,11 +146,11 @@ func auditContainer(container *k8s.ContainerV1, resource k8s.Resource) *kubeaudi
 				"Container":         container.Name,
 				"MissingAnnotation": containerAnnotation,
 			},
-			PendingFix: &fix.ByAddingPodAnnotation{
+			PendingFix: missingOrDisabledFix(container, config, containerAnnotation, &fix.ByAddingPodAnnotation{
 				Key:   containerAnnotation,
 				Value: ProfileRuntimeDefault,
-			},
-		}
+			}),
+		}}
 	}
 
 	if isAppArmorDisabled(containerAnnotation, annotations) {
@@ -95,8 +160,8 @@ func auditContainer(container *k8s.ContainerV1, resource k8s.Resource) *kubeaudi
 		} else {
 			rule = AppArmorBadValue
 		}
-		
-		return &kubeaudit.AuditResult{
+
+		return []*kubeaudit.AuditResult{{
 			Auditor:  Name,
 			Rule:     rule,
 			Message:  fmt.Sprintf("AppArmor is disabled. The apparmor annotation should be set to '%s' or start with '%s'.", ProfileRuntimeDefault, ProfileNamePrefix),
@@ -106,14 +171,128 @@ func auditContainer(container *k8s.ContainerV1, resource k8s.Resource) *kubeaudi
 				"Annotation":      containerAnnotation,
 				"AnnotationValue": getProfileName(containerAnnotation, annotations),
 			},
-			PendingFix: &fix.BySettingPodAnnotation{
+			PendingFix: missingOrDisabledFix(container, config, containerAnnotation, &fix.BySettingPodAnnotation{
 				Key:   containerAnnotation,
 				Value: ProfileRuntimeDefault,
+			}),
+		}}
+	}
+
+	return nil
+}
+
+// missingOrDisabledFix returns fallback, unless Config.GenerateProfileDir is set, in which case it returns a
+// fix that generates a starter AppArmor profile and points the container at it instead.
+func missingOrDisabledFix(container *k8s.ContainerV1, config Config, containerAnnotation string, fallback kubeaudit.PendingFix) kubeaudit.PendingFix {
+	if config.GenerateProfileDir == "" {
+		return fallback
+	}
+	return &fix.ByEmittingLocalhostProfile{
+		Container:     container.Name,
+		Dir:           config.GenerateProfileDir,
+		AnnotationKey: containerAnnotation,
+	}
+}
+
+// auditTypedProfile audits a container against the resolved typed AppArmor profile (whichever of container-level
+// or pod-level applies). podLevel indicates profile came from the pod's securityContext because the container
+// has no override of its own, in which case the fix must be applied at the pod level too.
+func auditTypedProfile(container *k8s.ContainerV1, profile *v1.AppArmorProfile, podLevel bool, config Config) *kubeaudit.AuditResult {
+	defaultFix := defaultAppArmorFix(container, podLevel)
+
+	switch profile.Type {
+	case v1.AppArmorProfileTypeRuntimeDefault, v1.AppArmorProfileTypeLocalhost:
+		return nil
+	case v1.AppArmorProfileTypeUnconfined:
+		return &kubeaudit.AuditResult{
+			Auditor:  Name,
+			Rule:     AppArmorDisabled,
+			Severity: kubeaudit.Error,
+			Message:  fmt.Sprintf("AppArmor is disabled. The appArmorProfile type should be '%s' or '%s'.", v1.AppArmorProfileTypeRuntimeDefault, v1.AppArmorProfileTypeLocalhost),
+			Metadata: kubeaudit.Metadata{
+				"Container":   container.Name,
+				"ProfileType": string(profile.Type),
 			},
+			PendingFix: missingOrDisabledFix(container, config, getContainerAnnotation(container), defaultFix),
+		}
+	default:
+		return &kubeaudit.AuditResult{
+			Auditor:  Name,
+			Rule:     AppArmorBadValue,
+			Severity: kubeaudit.Error,
+			Message:  fmt.Sprintf("AppArmor appArmorProfile type '%s' is not recognized.", profile.Type),
+			Metadata: kubeaudit.Metadata{
+				"Container":   container.Name,
+				"ProfileType": string(profile.Type),
+			},
+			PendingFix: missingOrDisabledFix(container, config, getContainerAnnotation(container), defaultFix),
 		}
 	}
+}
 
-	return nil
+// defaultAppArmorFix returns the fix for a container whose (effective) appArmorProfile needs to be reset to
+// runtime/default: at the pod level if podLevel (the container has no profile of its own, so the violation is
+// the pod's), otherwise at the container level.
+func defaultAppArmorFix(container *k8s.ContainerV1, podLevel bool) kubeaudit.PendingFix {
+	profile := &v1.AppArmorProfile{Type: v1.AppArmorProfileTypeRuntimeDefault}
+	if podLevel {
+		return &fix.BySettingPodAppArmorProfile{Profile: profile}
+	}
+	return &fix.BySettingContainerAppArmorProfile{
+		Container: container.Name,
+		Profile:   profile,
+	}
+}
+
+// auditProfileConflict flags containers where the legacy annotation and the typed appArmorProfile field are both
+// present but specify different profiles.
+func auditProfileConflict(container *k8s.ContainerV1, typedProfile *v1.AppArmorProfile, containerAnnotation string, annotations map[string]string) *kubeaudit.AuditResult {
+	annotationValue, ok := annotations[containerAnnotation]
+	if !ok || profileMatchesAnnotation(typedProfile, annotationValue) {
+		return nil
+	}
+
+	return &kubeaudit.AuditResult{
+		Auditor:  Name,
+		Rule:     AppArmorProfileConflict,
+		Severity: kubeaudit.Error,
+		Message:  fmt.Sprintf("AppArmor annotation '%s: %s' conflicts with the typed appArmorProfile (type=%s). Remove the annotation in favor of the typed field.", containerAnnotation, annotationValue, typedProfile.Type),
+		Metadata: kubeaudit.Metadata{
+			"Container":   container.Name,
+			"Annotation":  fmt.Sprintf("%s: %s", containerAnnotation, annotationValue),
+			"ProfileType": string(typedProfile.Type),
+		},
+		PendingFix: &fix.ByRemovingPodAnnotations{
+			Keys: []string{containerAnnotation},
+		},
+	}
+}
+
+// auditDeprecatedAnnotation flags containers which only use the legacy annotation when the manifest targets a
+// Kubernetes version new enough to support the typed appArmorProfile field instead. It only fires when the
+// annotation value is itself compliant (runtime/default or localhost/*) - if AppArmor is actually disabled or
+// set to a bad value, auditContainer's normal checks must run instead so that AppArmorDisabled/AppArmorBadValue
+// isn't masked by the lower-severity deprecation warning.
+func auditDeprecatedAnnotation(container *k8s.ContainerV1, containerAnnotation string, annotations map[string]string, config Config) *kubeaudit.AuditResult {
+	annotationValue, ok := annotations[containerAnnotation]
+	if !ok || isAppArmorDisabled(containerAnnotation, annotations) || !kubeVersionAtLeast(config.MinKubeVersion, minTypedProfileKubeVersion) {
+		return nil
+	}
+
+	return &kubeaudit.AuditResult{
+		Auditor:  Name,
+		Rule:     AppArmorDeprecatedAnnotation,
+		Severity: kubeaudit.Warning,
+		Message:  fmt.Sprintf("AppArmor annotation '%s' is deprecated as of Kubernetes %s. Use securityContext.appArmorProfile instead.", containerAnnotation, minTypedProfileKubeVersion),
+		Metadata: kubeaudit.Metadata{
+			"Container":  container.Name,
+			"Annotation": fmt.Sprintf("%s: %s", containerAnnotation, annotationValue),
+		},
+		PendingFix: &fix.BySettingContainerAppArmorProfile{
+			Container: container.Name,
+			Profile:   annotationValueToProfile(annotationValue),
+		},
+	}
 }
 
 func applyDisabledOverride(auditResult *kubeaudit.AuditResult, containerName string, resource k8s.Resource) *kubeaudit.AuditResult {
@@ -123,6 +302,9 @@ func applyDisabledOverride(auditResult *kubeaudit.AuditResult, containerName str
 	return override.ApplyOverride(auditResult, Name, containerName, resource, OverrideLabel)
 }
 
+// auditPodAnnotations flags apparmor annotations that refer to a container name which doesn't exist.
+// containerNames includes ephemeral container names when Config.IncludeEphemeralContainers is set, so
+// annotations targeting a real ephemeral container are not flagged as invalid.
 func auditPodAnnotations(resource k8s.Resource, containerNames []string) []*kubeaudit.AuditResult {
 	var auditResults []*kubeaudit.AuditResult
 	for annotationKey, annotationValue := range k8s.GetAnnotations(resource) {
@@ -173,6 +355,89 @@ func getProfileName(apparmorAnnotation string, annotations map[string]string) st
 	return profileName
 }
 
+// getContainerAppArmorProfile returns the typed appArmorProfile set directly on the container's securityContext,
+// or nil if unset.
+func getContainerAppArmorProfile(container *k8s.ContainerV1) *v1.AppArmorProfile {
+	if container.SecurityContext == nil {
+		return nil
+	}
+	return container.SecurityContext.AppArmorProfile
+}
+
+// getPodAppArmorProfile returns the typed appArmorProfile set on the pod's securityContext, or nil if unset.
+func getPodAppArmorProfile(resource k8s.Resource) *v1.AppArmorProfile {
+	podSpec := k8s.GetPodSpec(resource)
+	if podSpec == nil || podSpec.SecurityContext == nil {
+		return nil
+	}
+	return podSpec.SecurityContext.AppArmorProfile
+}
+
+// profileMatchesAnnotation reports whether a typed AppArmor profile is equivalent to a legacy annotation value.
+func profileMatchesAnnotation(profile *v1.AppArmorProfile, annotationValue string) bool {
+	switch profile.Type {
+	case v1.AppArmorProfileTypeRuntimeDefault:
+		return annotationValue == ProfileRuntimeDefault
+	case v1.AppArmorProfileTypeUnconfined:
+		return annotationValue == ProfileUnconfined
+	case v1.AppArmorProfileTypeLocalhost:
+		return profile.LocalhostProfile != nil && annotationValue == ProfileNamePrefix+*profile.LocalhostProfile
+	default:
+		return false
+	}
+}
+
+// annotationValueToProfile converts a legacy annotation value to its typed profile equivalent.
+func annotationValueToProfile(annotationValue string) *v1.AppArmorProfile {
+	switch {
+	case annotationValue == ProfileRuntimeDefault:
+		return &v1.AppArmorProfile{Type: v1.AppArmorProfileTypeRuntimeDefault}
+	case annotationValue == ProfileUnconfined:
+		return &v1.AppArmorProfile{Type: v1.AppArmorProfileTypeUnconfined}
+	case strings.HasPrefix(annotationValue, ProfileNamePrefix):
+		localhostProfile := strings.TrimPrefix(annotationValue, ProfileNamePrefix)
+		return &v1.AppArmorProfile{Type: v1.AppArmorProfileTypeLocalhost, LocalhostProfile: &localhostProfile}
+	default:
+		return &v1.AppArmorProfile{Type: v1.AppArmorProfileTypeRuntimeDefault}
+	}
+}
+
+// kubeVersionAtLeast reports whether version is set and >= min, comparing major.minor only. Returns false if
+// version is empty or unparseable.
+func kubeVersionAtLeast(version, min string) bool {
+	if version == "" {
+		return false
+	}
+	vMajor, vMinor, ok := parseMajorMinor(version)
+	if !ok {
+		return false
+	}
+	minMajor, minMinor, ok := parseMajorMinor(min)
+	if !ok {
+		return false
+	}
+	if vMajor != minMajor {
+		return vMajor > minMajor
+	}
+	return vMinor >= minMinor
+}
+
+func parseMajorMinor(version string) (int, int, bool) {
+	parts := strings.SplitN(strings.TrimPrefix(version, "v"), ".", 3)
+	if len(parts) < 2 {
+		return 0, 0, false
+	}
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, 0, false
+	}
+	minor, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, 0, false
+	}
+	return major, minor, true
+}
+
 func contains(arr []string, val string) bool {
 	for _, arrVal := range arr {
 		if arrVal == val {
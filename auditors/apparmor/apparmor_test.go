@@ -0,0 +1,95 @@
+package apparmor
+
+import (
+	"testing"
+
+	"github.com/elliotxx/kubeaudit/pkg/fix"
+	"github.com/elliotxx/kubeaudit/pkg/k8s"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestAudit_EphemeralContainers(t *testing.T) {
+	newResource := func() k8s.Resource {
+		return &k8s.PodV1{Spec: v1.PodSpec{
+			Containers: []v1.Container{
+				{Name: "container", SecurityContext: &v1.SecurityContext{
+					AppArmorProfile: &v1.AppArmorProfile{Type: v1.AppArmorProfileTypeRuntimeDefault},
+				}},
+			},
+			EphemeralContainers: []v1.EphemeralContainer{
+				{EphemeralContainerCommon: v1.EphemeralContainerCommon{Name: "debug"}},
+			},
+		}}
+	}
+
+	t.Run("ignored when IncludeEphemeralContainers is unset", func(t *testing.T) {
+		auditor := New(Config{})
+		results, err := auditor.Audit(newResource(), nil)
+		require.NoError(t, err)
+		assert.Empty(t, results)
+	})
+
+	t.Run("audited like a regular container when IncludeEphemeralContainers is set", func(t *testing.T) {
+		auditor := New(Config{IncludeEphemeralContainers: true})
+		results, err := auditor.Audit(newResource(), nil)
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.Equal(t, AppArmorAnnotationMissing, results[0].Rule)
+		assert.Equal(t, "debug", results[0].Metadata["Container"])
+	})
+
+	t.Run("fix adds the annotation naming the ephemeral container", func(t *testing.T) {
+		auditor := New(Config{IncludeEphemeralContainers: true})
+		resource := newResource()
+		results, err := auditor.Audit(resource, nil)
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+
+		results[0].PendingFix.Apply(resource)
+
+		annotations := k8s.GetAnnotations(resource)
+		assert.Equal(t, ProfileRuntimeDefault, annotations[ContainerAnnotationKeyPrefix+"debug"])
+	})
+}
+
+func TestAudit_PodLevelProfile(t *testing.T) {
+	// The container has no appArmorProfile of its own, so the pod-level profile applies to it and any fix must be
+	// applied at the pod level rather than the container level.
+	resource := &k8s.PodV1{Spec: v1.PodSpec{
+		SecurityContext: &v1.PodSecurityContext{
+			AppArmorProfile: &v1.AppArmorProfile{Type: v1.AppArmorProfileTypeUnconfined},
+		},
+		Containers: []v1.Container{{Name: "container"}},
+	}}
+
+	auditor := New(Config{})
+	results, err := auditor.Audit(resource, nil)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, AppArmorDisabled, results[0].Rule)
+	assert.IsType(t, &fix.BySettingPodAppArmorProfile{}, results[0].PendingFix)
+}
+
+func TestAudit_ContainerLevelProfile(t *testing.T) {
+	// The container has its own appArmorProfile overriding the pod's, so the violation and its fix are
+	// container-level.
+	resource := &k8s.PodV1{Spec: v1.PodSpec{
+		SecurityContext: &v1.PodSecurityContext{
+			AppArmorProfile: &v1.AppArmorProfile{Type: v1.AppArmorProfileTypeRuntimeDefault},
+		},
+		Containers: []v1.Container{
+			{Name: "container", SecurityContext: &v1.SecurityContext{
+				AppArmorProfile: &v1.AppArmorProfile{Type: v1.AppArmorProfileTypeUnconfined},
+			}},
+		},
+	}}
+
+	auditor := New(Config{})
+	results, err := auditor.Audit(resource, nil)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, AppArmorDisabled, results[0].Rule)
+	assert.IsType(t, &fix.BySettingContainerAppArmorProfile{}, results[0].PendingFix)
+}
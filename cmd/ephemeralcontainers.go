@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"github.com/elliotxx/kubeaudit"
+	"github.com/elliotxx/kubeaudit/auditors/apparmor"
+	"github.com/elliotxx/kubeaudit/config"
+)
+
+// includeEphemeralContainers, when set, causes auditors that support it (currently apparmor) to also audit a
+// pod's ephemeral (kubectl debug) containers. Off by default so existing manifests don't suddenly gain findings
+// for debug containers nobody intended to leave behind.
+var includeEphemeralContainers bool
+
+func init() {
+	RootCmd.PersistentFlags().BoolVar(&includeEphemeralContainers, "include-ephemeral-containers", false, "also audit ephemeral (kubectl debug) containers, for auditors that support it")
+}
+
+// applyIncludeEphemeralContainers overrides the apparmor auditor in auditors with --include-ephemeral-containers
+// wired in, if set. Like applyGenerateProfileDir, this is a post-construction override rather than something
+// threaded through config.KubeauditConfig: apparmor.Config.IncludeEphemeralContainers already flows end-to-end
+// through auditors/all.Auditors today (all.go passes the whole apparmor.Config from
+// conf.GetAuditorConfigs().AppArmor, not individual fields), so operators can already turn this on via the
+// on-disk config file. This flag just gives the same switch a CLI-only path, for runs that don't use a config
+// file at all.
+func applyIncludeEphemeralContainers(auditors []kubeaudit.Auditable, conf config.KubeauditConfig) {
+	if !includeEphemeralContainers {
+		return
+	}
+
+	appArmorConfig := conf.GetAuditorConfigs().AppArmor
+	appArmorConfig.IncludeEphemeralContainers = true
+	// Keep --generate-profile, which applyGenerateProfileDir may already have wired into this auditor.
+	if generateProfileDir != "" {
+		appArmorConfig.GenerateProfileDir = generateProfileDir
+	}
+
+	for i, auditor := range auditors {
+		if _, ok := auditor.(*apparmor.AppArmor); ok {
+			auditors[i] = apparmor.New(appArmorConfig)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"github.com/elliotxx/kubeaudit"
+	"github.com/elliotxx/kubeaudit/auditors/apparmor"
+	"github.com/elliotxx/kubeaudit/config"
+)
+
+// generateProfileDir, when non-empty, switches the apparmor auditor from fixes that merely point at the runtime
+// default profile to fixes that generate a starter AppArmor profile under this directory and point the
+// container at it instead. Only the apparmor auditor is affected; no seccomp profile is generated. Empty by
+// default so read-only audit runs never write to disk.
+var generateProfileDir string
+
+func init() {
+	RootCmd.PersistentFlags().StringVar(&generateProfileDir, "generate-profile", "", "directory to write starter AppArmor profiles to for containers missing one (leave unset to only flag them; seccomp profiles are not generated)")
+}
+
+// applyGenerateProfileDir overrides the apparmor auditor in auditors with --generate-profile wired in, if set.
+// It's applied as a post-construction override rather than threaded through config.KubeauditConfig because the
+// flag is a CLI-only concern, not something that belongs in the on-disk kubeaudit config file.
+func applyGenerateProfileDir(auditors []kubeaudit.Auditable, conf config.KubeauditConfig) {
+	if generateProfileDir == "" {
+		return
+	}
+
+	appArmorConfig := conf.GetAuditorConfigs().AppArmor
+	appArmorConfig.GenerateProfileDir = generateProfileDir
+
+	for i, auditor := range auditors {
+		if _, ok := auditor.(*apparmor.AppArmor); ok {
+			auditors[i] = apparmor.New(appArmorConfig)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/elliotxx/kubeaudit"
+	"github.com/elliotxx/kubeaudit/auditors/all"
+	"github.com/elliotxx/kubeaudit/config"
+	"github.com/elliotxx/kubeaudit/pkg/webhook"
+)
+
+var webhookConfig webhook.Config
+
+func newWebhookCmd() *cobra.Command {
+	var minSeverity string
+	var validateOnlyAuditors, mutateOnlyAuditors []string
+
+	cmd := &cobra.Command{
+		Use:   "webhook",
+		Short: "Run kubeaudit as an in-cluster validating/mutating admission webhook",
+		Long: `Run kubeaudit as an HTTPS server implementing the Kubernetes AdmissionReview v1 API.
+
+Validating requests are denied when an auditor reports a finding at or above --min-severity.
+Mutating requests have any available PendingFix applied and returned as a JSON patch.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			severity, err := parseSeverity(minSeverity)
+			if err != nil {
+				return err
+			}
+			webhookConfig.MinSeverity = severity
+
+			modes, err := parseAuditorModes(validateOnlyAuditors, mutateOnlyAuditors)
+			if err != nil {
+				return err
+			}
+			webhookConfig.AuditorModes = modes
+
+			conf, err := config.Load(cfgFile)
+			if err != nil {
+				return err
+			}
+			webhookConfig.KubeauditConfig = conf
+
+			auditors, err := all.Auditors(conf)
+			if err != nil {
+				return err
+			}
+			applyGenerateProfileDir(auditors, conf)
+			applyIncludeEphemeralContainers(auditors, conf)
+			webhookConfig.Auditors = auditors
+
+			return webhook.NewServer(webhookConfig).Run(cmd.Context())
+		},
+	}
+
+	cmd.Flags().StringVar(&webhookConfig.Addr, "listen-addr", ":8443", "address for the webhook HTTPS server to listen on")
+	cmd.Flags().StringVar(&webhookConfig.CertFile, "tls-cert-file", "", "path to the TLS certificate file (e.g. a cert-manager issued certificate)")
+	cmd.Flags().StringVar(&webhookConfig.KeyFile, "tls-key-file", "", "path to the TLS private key file")
+	cmd.Flags().StringVar(&minSeverity, "min-severity", "error", "minimum severity (info, warning, error) that causes a validating request to be denied")
+	cmd.Flags().StringSliceVar(&validateOnlyAuditors, "validate-only", nil, "names of auditors (see 'kubeaudit auditors') that should only deny, never mutate, in this webhook")
+	cmd.Flags().StringSliceVar(&mutateOnlyAuditors, "mutate-only", nil, "names of auditors (see 'kubeaudit auditors') that should only patch, never deny, in this webhook")
+
+	return cmd
+}
+
+// parseAuditorModes builds the AuditorModes restriction from --validate-only/--mutate-only. An auditor named in
+// both flags keeps both modes; an auditor named in neither keeps kubeaudit's default of participating in both
+// (see webhook.Server.auditorEnabledFor).
+func parseAuditorModes(validateOnly, mutateOnly []string) (map[string]webhook.Mode, error) {
+	if len(validateOnly) == 0 && len(mutateOnly) == 0 {
+		return nil, nil
+	}
+
+	modes := map[string]webhook.Mode{}
+	add := func(names []string, mode webhook.Mode) error {
+		for _, name := range names {
+			if !knownAuditor(name) {
+				return fmt.Errorf("unknown auditor %q", name)
+			}
+			modes[name] |= mode
+		}
+		return nil
+	}
+	if err := add(validateOnly, webhook.ModeValidate); err != nil {
+		return nil, err
+	}
+	if err := add(mutateOnly, webhook.ModeMutate); err != nil {
+		return nil, err
+	}
+	return modes, nil
+}
+
+func knownAuditor(name string) bool {
+	for _, known := range all.AuditorNames {
+		if known == name {
+			return true
+		}
+	}
+	return false
+}
+
+func parseSeverity(severity string) (kubeaudit.Severity, error) {
+	switch strings.ToLower(severity) {
+	case "info":
+		return kubeaudit.Info, nil
+	case "warning":
+		return kubeaudit.Warning, nil
+	case "error":
+		return kubeaudit.Error, nil
+	default:
+		return 0, fmt.Errorf("unknown severity %q: expected info, warning or error", severity)
+	}
+}
+
+func init() {
+	RootCmd.AddCommand(newWebhookCmd())
+}
@@ -27,3 +27,11 @@ func init() {
 	v1.AddToGroupVersion(scheme, schema.GroupVersion{Version: "v1"})
 	utilruntime.Must(addToScheme(scheme))
 }
+
+// DecodeRaw decodes raw JSON or YAML bytes for a single Kubernetes object (such as an AdmissionRequest's
+// Object.Raw) into a runtime.Object using the kubeaudit scheme, so webhook-admitted resources are decoded the
+// same way as manifests loaded from disk.
+func DecodeRaw(raw []byte) (runtime.Object, error) {
+	obj, _, err := codecs.UniversalDeserializer().Decode(raw, nil, nil)
+	return obj, err
+}
@@ -0,0 +1,137 @@
+package sarif
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/elliotxx/kubeaudit"
+)
+
+// toolName and toolInfoURI identify kubeaudit in the SARIF tool.driver object.
+const (
+	toolName    = "kubeaudit"
+	toolInfoURI = "https://github.com/elliotxx/kubeaudit"
+)
+
+// Finding pairs an AuditResult with the location of the offending resource in its source manifest, and the
+// resource's namespace/kind/name so a stable fingerprint can be computed.
+type Finding struct {
+	Result *kubeaudit.AuditResult
+
+	// File is the manifest path the resource was read from.
+	File string
+	// Line and Column locate the resource within File, when known. Zero means unknown.
+	Line, Column int
+
+	Namespace string
+	Kind      string
+	Name      string
+}
+
+// Report builds a SARIF 2.1.0 log from a set of kubeaudit findings.
+func Report(findings []Finding) Log {
+	rules := map[string]Rule{}
+	results := make([]Result, 0, len(findings))
+
+	for _, finding := range findings {
+		result := finding.Result
+		if _, ok := rules[result.Rule]; !ok {
+			rules[result.Rule] = newRule(result)
+		}
+		results = append(results, newResult(finding))
+	}
+
+	return Log{
+		Schema:  schemaURI,
+		Version: version,
+		Runs: []Run{
+			{
+				Tool: Tool{
+					Driver: Driver{
+						Name:           toolName,
+						InformationURI: toolInfoURI,
+						Rules:          sortedRules(rules),
+					},
+				},
+				Results: results,
+			},
+		},
+	}
+}
+
+// WriteLog marshals a SARIF log for findings to w.
+func WriteLog(w io.Writer, findings []Finding) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(Report(findings))
+}
+
+func newRule(result *kubeaudit.AuditResult) Rule {
+	descriptor := describeRule(result.Auditor, result.Rule)
+	return Rule{
+		ID:               result.Rule,
+		Name:             result.Rule,
+		ShortDescription: Message{Text: descriptor.shortDescription},
+		FullDescription:  Message{Text: descriptor.fullDescription},
+		HelpURI:          helpURI(result.Rule),
+		DefaultConfiguration: RuleConfiguration{
+			Level: sarifLevel(result.Severity),
+		},
+		Properties: map[string]string{
+			"auditor": result.Auditor,
+		},
+	}
+}
+
+func newResult(finding Finding) Result {
+	result := finding.Result
+
+	return Result{
+		RuleID:              result.Rule,
+		Level:               sarifLevel(result.Severity),
+		Message:             Message{Text: result.Message},
+		Locations:           []Location{newLocation(finding)},
+		Properties:          map[string]string(result.Metadata),
+		PartialFingerprints: map[string]string{"kubeaudit/v1": fingerprint(result, finding)},
+	}
+}
+
+func newLocation(finding Finding) Location {
+	var region *Region
+	if finding.Line > 0 {
+		region = &Region{StartLine: finding.Line, StartColumn: finding.Column}
+	}
+	return Location{
+		PhysicalLocation: PhysicalLocation{
+			ArtifactLocation: ArtifactLocation{URI: finding.File},
+			Region:           region,
+		},
+	}
+}
+
+// fingerprint computes a stable partial fingerprint so GitHub code scanning can dedupe the same finding across
+// runs even as unrelated parts of the manifest change.
+func fingerprint(result *kubeaudit.AuditResult, finding Finding) string {
+	container := result.Metadata["Container"]
+	raw := fmt.Sprintf("%s|%s|%s|%s|%s|%s", result.Auditor, result.Rule, finding.Namespace, finding.Kind, finding.Name, container)
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
+
+func sortedRules(rules map[string]Rule) []Rule {
+	ids := make([]string, 0, len(rules))
+	for id := range rules {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	sorted := make([]Rule, 0, len(rules))
+	for _, id := range ids {
+		sorted = append(sorted, rules[id])
+	}
+	return sorted
+}
@@ -0,0 +1,81 @@
+package sarif
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/elliotxx/kubeaudit"
+	"github.com/elliotxx/kubeaudit/auditors/apparmor"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReport(t *testing.T) {
+	findings := []Finding{
+		{
+			Result: &kubeaudit.AuditResult{
+				Auditor:  apparmor.Name,
+				Rule:     apparmor.AppArmorDisabled,
+				Severity: kubeaudit.Error,
+				Message:  "AppArmor is disabled. The apparmor annotation should be set to 'runtime/default' or start with 'localhost/'.",
+				Metadata: kubeaudit.Metadata{
+					"Container":       "nginx",
+					"Annotation":      "container.apparmor.security.beta.kubernetes.io/nginx",
+					"AnnotationValue": "unconfined",
+				},
+			},
+			File:      "testdata/pod.yml",
+			Line:      12,
+			Column:    5,
+			Namespace: "default",
+			Kind:      "Pod",
+			Name:      "nginx",
+		},
+	}
+
+	log := Report(findings)
+	validateAgainstSarifSchema(t, log)
+
+	actual, err := json.MarshalIndent(log, "", "  ")
+	require.NoError(t, err)
+
+	golden := filepath.Join("testdata", "report.golden.sarif")
+	expected, err := os.ReadFile(golden)
+	require.NoError(t, err)
+
+	assert.JSONEq(t, string(expected), string(actual))
+}
+
+// sarifSchemaPath is a hand-authored JSON Schema covering only the subset of SARIF 2.1.0 this package's Report()
+// ever emits, not the full ~1600-line OASIS schema (https://json.schemastore.org/sarif-2.1.0.json) - vendoring
+// that wholesale would validate far more than this package needs to satisfy. See testdata/ for its scope.
+const sarifSchemaPath = "testdata/sarif-2.1.0-subset.schema.json"
+
+// validateAgainstSarifSchema validates log's JSON encoding against sarifSchemaPath using a real JSON Schema
+// validator, then checks the one invariant JSON Schema can't express on its own: that every result's ruleId
+// refers to a rule actually declared in tool.driver.rules.
+func validateAgainstSarifSchema(t *testing.T, log Log) {
+	t.Helper()
+
+	schema, err := jsonschema.Compile(sarifSchemaPath)
+	require.NoError(t, err)
+
+	encoded, err := json.Marshal(log)
+	require.NoError(t, err)
+
+	var decoded any
+	require.NoError(t, json.Unmarshal(encoded, &decoded))
+
+	assert.NoError(t, schema.Validate(decoded))
+
+	declaredRules := map[string]bool{}
+	for _, rule := range log.Runs[0].Tool.Driver.Rules {
+		declaredRules[rule.ID] = true
+	}
+	for _, result := range log.Runs[0].Results {
+		assert.True(t, declaredRules[result.RuleID], "result references undeclared rule %q", result.RuleID)
+	}
+}
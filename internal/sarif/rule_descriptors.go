@@ -0,0 +1,80 @@
+package sarif
+
+import (
+	"fmt"
+
+	"github.com/elliotxx/kubeaudit"
+	"github.com/elliotxx/kubeaudit/auditors/apparmor"
+)
+
+const docsBaseURL = "https://github.com/elliotxx/kubeaudit/blob/main/docs/auditors.md"
+
+// ruleDescriptor carries the static, rule-level descriptions SARIF needs in tool.driver.rules[]: a one-line and a
+// longer description. The documentation anchor for helpUri is built separately by helpURI.
+type ruleDescriptor struct {
+	shortDescription string
+	fullDescription  string
+}
+
+// ruleDescriptors maps rule constants to their SARIF metadata. Auditors are added here as their rules gain
+// SARIF support; rules missing an entry fall back to a generic description derived from their name.
+var ruleDescriptors = map[string]ruleDescriptor{
+	apparmor.AppArmorAnnotationMissing: {
+		shortDescription: "AppArmor annotation or appArmorProfile missing",
+		fullDescription:  "The container has neither the container.apparmor.security.beta.kubernetes.io/<name> annotation nor a typed securityContext.appArmorProfile, so AppArmor is not explicitly configured.",
+	},
+	apparmor.AppArmorDisabled: {
+		shortDescription: "AppArmor disabled",
+		fullDescription:  "The container's AppArmor profile (annotation or typed securityContext.appArmorProfile) is explicitly set to unconfined.",
+	},
+	apparmor.AppArmorBadValue: {
+		shortDescription: "AppArmor profile value not recognized",
+		fullDescription:  "The container's AppArmor profile is set to a value that is neither runtime/default, a localhost/ profile, nor unconfined.",
+	},
+	apparmor.AppArmorInvalidAnnotation: {
+		shortDescription: "AppArmor annotation refers to a missing container",
+		fullDescription:  "An apparmor annotation key refers to a container name that doesn't exist in the pod, which will prevent the manifest from being applied to a cluster with AppArmor enabled.",
+	},
+	apparmor.AppArmorProfileConflict: {
+		shortDescription: "AppArmor annotation conflicts with typed profile",
+		fullDescription:  "The legacy apparmor annotation and the typed securityContext.appArmorProfile field are both set but specify different profiles.",
+	},
+	apparmor.AppArmorDeprecatedAnnotation: {
+		shortDescription: "AppArmor annotation is deprecated",
+		fullDescription:  "Only the legacy apparmor annotation is set, but the manifest targets a Kubernetes version where securityContext.appArmorProfile should be used instead.",
+	},
+}
+
+// describeRule returns the SARIF rule metadata for ruleID, falling back to a generic description built from
+// auditorDescriptions and the rule name for rules not yet in ruleDescriptors.
+func describeRule(auditorName, ruleID string) ruleDescriptor {
+	if descriptor, ok := ruleDescriptors[ruleID]; ok {
+		return descriptor
+	}
+
+	auditorDescription, ok := auditorDescriptions[auditorName]
+	if !ok {
+		auditorDescription = fmt.Sprintf("the %s auditor", auditorName)
+	}
+	return ruleDescriptor{
+		shortDescription: ruleID,
+		fullDescription:  fmt.Sprintf("%s. Reported by the %s auditor's %s rule.", auditorDescription, auditorName, ruleID),
+	}
+}
+
+// helpURI returns the documentation anchor for a rule.
+func helpURI(ruleID string) string {
+	return fmt.Sprintf("%s#%s", docsBaseURL, ruleID)
+}
+
+// sarifLevel translates a kubeaudit.Severity into a SARIF result/rule level.
+func sarifLevel(severity kubeaudit.Severity) string {
+	switch severity {
+	case kubeaudit.Error:
+		return "error"
+	case kubeaudit.Warning:
+		return "warning"
+	default:
+		return "note"
+	}
+}
@@ -17,7 +17,9 @@ import (
 	"github.com/elliotxx/kubeaudit/auditors/seccomp"
 )
 
-var allAuditors = map[string]string{
+// auditorDescriptions gives a one-line description of each auditor, used by describeRule's fallback when a rule
+// doesn't have its own entry in ruleDescriptors.
+var auditorDescriptions = map[string]string{
 	apparmor.Name:       "Finds containers that do not have AppArmor enabled",
 	asat.Name:           "Finds containers where the deprecated SA field is used or with a mounted default SA",
 	capabilities.Name:   "Finds containers that do not drop the recommended capabilities or add new ones",
@@ -0,0 +1,88 @@
+package sarif
+
+// The types below are a minimal implementation of the SARIF 2.1.0 object model
+// (https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.json), covering only the fields this package
+// populates.
+
+const schemaURI = "https://docs.oasis-open.org/sarif/sarif/v2.1.0/errata01/os/schemas/sarif-schema-2.1.0.json"
+const version = "2.1.0"
+
+// Log is the SARIF log file root object.
+type Log struct {
+	Schema  string `json:"$schema"`
+	Version string `json:"version"`
+	Runs    []Run  `json:"runs"`
+}
+
+// Run describes a single run of kubeaudit.
+type Run struct {
+	Tool    Tool     `json:"tool"`
+	Results []Result `json:"results"`
+}
+
+// Tool describes kubeaudit and the rules it can report.
+type Tool struct {
+	Driver Driver `json:"driver"`
+}
+
+// Driver describes kubeaudit itself and the rules referenced by the run's results. Rules that no result
+// references are not listed.
+type Driver struct {
+	Name           string `json:"name"`
+	InformationURI string `json:"informationUri"`
+	Version        string `json:"version,omitempty"`
+	Rules          []Rule `json:"rules"`
+}
+
+// Rule is a `tool.driver.rules[]` entry describing one auditor rule constant.
+type Rule struct {
+	ID                   string            `json:"id"`
+	Name                 string            `json:"name,omitempty"`
+	ShortDescription     Message           `json:"shortDescription"`
+	FullDescription      Message           `json:"fullDescription"`
+	HelpURI              string            `json:"helpUri,omitempty"`
+	DefaultConfiguration RuleConfiguration `json:"defaultConfiguration"`
+	Properties           map[string]string `json:"properties,omitempty"`
+}
+
+// RuleConfiguration carries the rule's default reporting configuration.
+type RuleConfiguration struct {
+	Level string `json:"level"`
+}
+
+// Message is SARIF's wrapper for a plain text string.
+type Message struct {
+	Text string `json:"text"`
+}
+
+// Result is a single `results[]` entry: one finding from one auditor against one resource.
+type Result struct {
+	RuleID              string            `json:"ruleId"`
+	Level               string            `json:"level"`
+	Message             Message           `json:"message"`
+	Locations           []Location        `json:"locations,omitempty"`
+	Properties          map[string]string `json:"properties,omitempty"`
+	PartialFingerprints map[string]string `json:"partialFingerprints,omitempty"`
+}
+
+// Location points at the offending resource in its source manifest.
+type Location struct {
+	PhysicalLocation PhysicalLocation `json:"physicalLocation"`
+}
+
+// PhysicalLocation identifies a manifest file and, when known, the line/column of the resource within it.
+type PhysicalLocation struct {
+	ArtifactLocation ArtifactLocation `json:"artifactLocation"`
+	Region           *Region          `json:"region,omitempty"`
+}
+
+// ArtifactLocation identifies the manifest file a finding was read from.
+type ArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+// Region identifies a line/column within an ArtifactLocation.
+type Region struct {
+	StartLine   int `json:"startLine,omitempty"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
@@ -0,0 +1,69 @@
+package fix
+
+import (
+	"fmt"
+
+	"github.com/elliotxx/kubeaudit/pkg/k8s"
+	v1 "k8s.io/api/core/v1"
+)
+
+// BySettingContainerAppArmorProfile fixes AppArmor findings by setting the typed
+// securityContext.appArmorProfile field on a specific container.
+type BySettingContainerAppArmorProfile struct {
+	Container string
+	Profile   *v1.AppArmorProfile
+}
+
+func (fix *BySettingContainerAppArmorProfile) Plan() string {
+	return fmt.Sprintf("Set appArmorProfile (type=%s) on container '%s'", fix.Profile.Type, fix.Container)
+}
+
+func (fix *BySettingContainerAppArmorProfile) Apply(resource k8s.Resource) {
+	setContainerSecurityContext(resource, fix.Container, func(secCtx *v1.SecurityContext) {
+		secCtx.AppArmorProfile = fix.Profile
+	})
+}
+
+// setContainerSecurityContext finds the container (regular or, failing that, ephemeral) named name and calls
+// mutate with its SecurityContext, allocating one first if necessary. It is a no-op if no such container exists.
+func setContainerSecurityContext(resource k8s.Resource, name string, mutate func(secCtx *v1.SecurityContext)) {
+	for _, container := range k8s.GetContainers(resource) {
+		if container.Name != name {
+			continue
+		}
+		if container.SecurityContext == nil {
+			container.SecurityContext = &v1.SecurityContext{}
+		}
+		mutate(container.SecurityContext)
+		return
+	}
+
+	// name may be an ephemeral (kubectl debug) container, which GetContainers doesn't return.
+	if ephemeral := k8s.EphemeralContainerByName(resource, name); ephemeral != nil {
+		if ephemeral.SecurityContext == nil {
+			ephemeral.SecurityContext = &v1.SecurityContext{}
+		}
+		mutate(ephemeral.SecurityContext)
+	}
+}
+
+// BySettingPodAppArmorProfile fixes AppArmor findings by setting the typed
+// securityContext.appArmorProfile field at the pod level.
+type BySettingPodAppArmorProfile struct {
+	Profile *v1.AppArmorProfile
+}
+
+func (fix *BySettingPodAppArmorProfile) Plan() string {
+	return fmt.Sprintf("Set pod-level appArmorProfile (type=%s)", fix.Profile.Type)
+}
+
+func (fix *BySettingPodAppArmorProfile) Apply(resource k8s.Resource) {
+	podSpec := k8s.GetPodSpec(resource)
+	if podSpec == nil {
+		return
+	}
+	if podSpec.SecurityContext == nil {
+		podSpec.SecurityContext = &v1.PodSecurityContext{}
+	}
+	podSpec.SecurityContext.AppArmorProfile = fix.Profile
+}
@@ -0,0 +1,78 @@
+package fix
+
+import (
+	"testing"
+
+	"github.com/elliotxx/kubeaudit"
+	"github.com/elliotxx/kubeaudit/pkg/k8s"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestAppArmorFix(t *testing.T) {
+	cases := []struct {
+		testName    string
+		pendingFix  kubeaudit.PendingFix
+		preFix      func(resource k8s.Resource)
+		assertFixed func(t *testing.T, resource k8s.Resource)
+	}{
+		{
+			testName: "BySettingContainerAppArmorProfile",
+			pendingFix: &BySettingContainerAppArmorProfile{
+				Container: "container",
+				Profile:   &v1.AppArmorProfile{Type: v1.AppArmorProfileTypeRuntimeDefault},
+			},
+			preFix: func(resource k8s.Resource) {},
+			assertFixed: func(t *testing.T, resource k8s.Resource) {
+				containers := k8s.GetContainers(resource)
+				assert.Len(t, containers, 1)
+				assert.NotNil(t, containers[0].SecurityContext)
+				assert.Equal(t, v1.AppArmorProfileTypeRuntimeDefault, containers[0].SecurityContext.AppArmorProfile.Type)
+			},
+		},
+		{
+			testName: "BySettingPodAppArmorProfile",
+			pendingFix: &BySettingPodAppArmorProfile{
+				Profile: &v1.AppArmorProfile{Type: v1.AppArmorProfileTypeRuntimeDefault},
+			},
+			preFix: func(resource k8s.Resource) {},
+			assertFixed: func(t *testing.T, resource k8s.Resource) {
+				podSpec := k8s.GetPodSpec(resource)
+				assert.NotNil(t, podSpec.SecurityContext)
+				assert.Equal(t, v1.AppArmorProfileTypeRuntimeDefault, podSpec.SecurityContext.AppArmorProfile.Type)
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.testName, func(t *testing.T) {
+			resource := &k8s.PodV1{Spec: v1.PodSpec{Containers: []v1.Container{{Name: "container"}}}}
+			tc.preFix(resource)
+			assert.NotEmpty(t, tc.pendingFix.Plan())
+			tc.pendingFix.Apply(resource)
+			tc.assertFixed(t, resource)
+		})
+	}
+}
+
+func TestBySettingContainerAppArmorProfile_EphemeralContainer(t *testing.T) {
+	resource := &k8s.PodV1{Spec: v1.PodSpec{
+		Containers: []v1.Container{{Name: "container"}},
+		EphemeralContainers: []v1.EphemeralContainer{
+			{EphemeralContainerCommon: v1.EphemeralContainerCommon{Name: "debug"}},
+		},
+	}}
+
+	pendingFix := &BySettingContainerAppArmorProfile{
+		Container: "debug",
+		Profile:   &v1.AppArmorProfile{Type: v1.AppArmorProfileTypeRuntimeDefault},
+	}
+	pendingFix.Apply(resource)
+
+	ephemeral := k8s.EphemeralContainerByName(resource, "debug")
+	assert.NotNil(t, ephemeral.SecurityContext)
+	assert.Equal(t, v1.AppArmorProfileTypeRuntimeDefault, ephemeral.SecurityContext.AppArmorProfile.Type)
+
+	// The regular container named "container" must be left untouched.
+	assert.Nil(t, k8s.GetContainers(resource)[0].SecurityContext)
+}
@@ -0,0 +1,160 @@
+package fix
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/elliotxx/kubeaudit/pkg/k8s"
+	v1 "k8s.io/api/core/v1"
+)
+
+// appArmorProfileTemplate is a minimal starter AppArmor profile in the spirit of the ones CRI-O/podman ship:
+// deny the operations most likely to be abused, allow the base abstractions, and leave the rest to be
+// tightened by hand.
+const appArmorProfileTemplate = `#include <tunables/global>
+
+profile %s flags=(attach_disconnected,mediate_deleted) {
+  #include <abstractions/base>
+
+  deny mount,
+  deny ptrace,
+  deny /proc/sys/** w,
+  deny /sys/** w,
+  deny /boot/** rwklx,
+}
+`
+
+// ByEmittingLocalhostProfile fixes a missing/disabled AppArmor finding by writing a starter profile to Dir and
+// pointing the container at the generated localhost/ profile, via the typed securityContext.appArmorProfile
+// field and, when AnnotationKey is set, the legacy annotation too.
+type ByEmittingLocalhostProfile struct {
+	// Container is the name of the container the profile is generated for.
+	Container string
+	// Dir is the directory the generated profile file is written to.
+	Dir string
+	// AnnotationKey, when set, is rewritten to point at the generated profile alongside the typed field.
+	AnnotationKey string
+}
+
+func (f *ByEmittingLocalhostProfile) profileName() string {
+	return fmt.Sprintf("kubeaudit-%s", f.Container)
+}
+
+func (f *ByEmittingLocalhostProfile) Plan() string {
+	return fmt.Sprintf("Generate an AppArmor profile for container '%s' under %s and point it at localhost/%s", f.Container, f.Dir, f.profileName())
+}
+
+func (f *ByEmittingLocalhostProfile) Apply(resource k8s.Resource) {
+	profileName := f.profileName()
+	path := filepath.Join(f.Dir, profileName)
+	contents := fmt.Sprintf(appArmorProfileTemplate, profileName)
+
+	// Dir is typically a fresh directory created specifically to hold generated profiles, so it may not exist
+	// yet on first use.
+	if err := os.MkdirAll(f.Dir, 0o755); err != nil {
+		return
+	}
+
+	// PendingFix.Apply has no error return; a write failure just leaves the profile unset rather than
+	// panicking on an otherwise successful audit run.
+	if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+		return
+	}
+
+	setContainerSecurityContext(resource, f.Container, func(secCtx *v1.SecurityContext) {
+		secCtx.AppArmorProfile = &v1.AppArmorProfile{
+			Type:             v1.AppArmorProfileTypeLocalhost,
+			LocalhostProfile: &profileName,
+		}
+	})
+
+	if f.AnnotationKey == "" {
+		return
+	}
+	objectMeta := k8s.GetPodObjectMeta(resource)
+	if objectMeta.Annotations == nil {
+		objectMeta.Annotations = map[string]string{}
+	}
+	objectMeta.Annotations[f.AnnotationKey] = "localhost/" + profileName
+}
+
+// seccompProfileSpec is the on-disk JSON shape the kubelet expects under its seccomp profile root, mirroring
+// the subset of fields Docker's own default seccomp profile uses.
+type seccompProfileSpec struct {
+	DefaultAction string              `json:"defaultAction"`
+	Architectures []string            `json:"architectures"`
+	Syscalls      []seccompSyscallSet `json:"syscalls"`
+}
+
+type seccompSyscallSet struct {
+	Names  []string `json:"names"`
+	Action string   `json:"action"`
+}
+
+// starterSeccompSyscalls is a minimal allowlist covering the syscalls almost every container needs to start up
+// (process/file/memory basics); everything else is denied by DefaultAction and left to be tightened by hand.
+var starterSeccompSyscalls = []string{
+	"accept", "bind", "brk", "chdir", "clone", "close", "connect", "dup", "dup2", "execve", "exit",
+	"exit_group", "fcntl", "fstat", "futex", "getcwd", "getdents64", "getpid", "listen", "lseek", "mmap",
+	"mprotect", "munmap", "open", "openat", "read", "readlink", "rt_sigaction", "rt_sigreturn", "sched_yield",
+	"set_robust_list", "set_tid_address", "socket", "stat", "wait4", "write",
+}
+
+// ByEmittingSeccompProfile fixes a missing/disabled seccomp finding by writing a starter JSON profile to Dir
+// and pointing the container at the generated localhost/ profile via the typed securityContext.seccompProfile
+// field.
+//
+// Note: the seccomp auditor does not construct this fix, so nothing currently does - it's added here, alongside
+// ByEmittingLocalhostProfile, so that auditor can wire it in via the same missingOrDisabledFix pattern apparmor
+// uses.
+type ByEmittingSeccompProfile struct {
+	// Container is the name of the container the profile is generated for.
+	Container string
+	// Dir is the directory the generated profile file is written to.
+	Dir string
+}
+
+func (f *ByEmittingSeccompProfile) profileName() string {
+	return fmt.Sprintf("kubeaudit-%s.json", f.Container)
+}
+
+func (f *ByEmittingSeccompProfile) Plan() string {
+	return fmt.Sprintf("Generate a seccomp profile for container '%s' under %s and point it at localhost/%s", f.Container, f.Dir, f.profileName())
+}
+
+func (f *ByEmittingSeccompProfile) Apply(resource k8s.Resource) {
+	profileName := f.profileName()
+	path := filepath.Join(f.Dir, profileName)
+
+	contents, err := json.MarshalIndent(seccompProfileSpec{
+		DefaultAction: "SCMP_ACT_ERRNO",
+		Architectures: []string{"SCMP_ARCH_X86_64"},
+		Syscalls: []seccompSyscallSet{
+			{Names: starterSeccompSyscalls, Action: "SCMP_ACT_ALLOW"},
+		},
+	}, "", "  ")
+	if err != nil {
+		return
+	}
+
+	// Dir is typically a fresh directory created specifically to hold generated profiles, so it may not exist
+	// yet on first use.
+	if err := os.MkdirAll(f.Dir, 0o755); err != nil {
+		return
+	}
+
+	// PendingFix.Apply has no error return; a write failure just leaves the profile unset rather than
+	// panicking on an otherwise successful audit run.
+	if err := os.WriteFile(path, contents, 0o644); err != nil {
+		return
+	}
+
+	setContainerSecurityContext(resource, f.Container, func(secCtx *v1.SecurityContext) {
+		secCtx.SeccompProfile = &v1.SeccompProfile{
+			Type:             v1.SeccompProfileTypeLocalhost,
+			LocalhostProfile: &profileName,
+		}
+	})
+}
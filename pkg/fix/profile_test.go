@@ -0,0 +1,109 @@
+package fix
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/elliotxx/kubeaudit/pkg/k8s"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestByEmittingLocalhostProfile(t *testing.T) {
+	dir := t.TempDir()
+	annotationKey := "container.apparmor.security.beta.kubernetes.io/container"
+
+	pendingFix := &ByEmittingLocalhostProfile{
+		Container:     "container",
+		Dir:           dir,
+		AnnotationKey: annotationKey,
+	}
+	assert.NotEmpty(t, pendingFix.Plan())
+
+	resource := &k8s.PodV1{Spec: v1.PodSpec{Containers: []v1.Container{{Name: "container"}}}}
+	pendingFix.Apply(resource)
+
+	contents, err := os.ReadFile(filepath.Join(dir, "kubeaudit-container"))
+	require.NoError(t, err)
+	assert.Contains(t, string(contents), "deny mount,")
+	assert.Contains(t, string(contents), "profile kubeaudit-container")
+
+	containers := k8s.GetContainers(resource)
+	require.Len(t, containers, 1)
+	require.NotNil(t, containers[0].SecurityContext)
+	require.NotNil(t, containers[0].SecurityContext.AppArmorProfile)
+	assert.Equal(t, v1.AppArmorProfileTypeLocalhost, containers[0].SecurityContext.AppArmorProfile.Type)
+	assert.Equal(t, "kubeaudit-container", *containers[0].SecurityContext.AppArmorProfile.LocalhostProfile)
+
+	annotations := k8s.GetAnnotations(resource)
+	assert.Equal(t, "localhost/kubeaudit-container", annotations[annotationKey])
+}
+
+func TestByEmittingLocalhostProfile_CreatesMissingDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "profiles")
+
+	pendingFix := &ByEmittingLocalhostProfile{
+		Container: "container",
+		Dir:       dir,
+	}
+
+	resource := &k8s.PodV1{Spec: v1.PodSpec{Containers: []v1.Container{{Name: "container"}}}}
+	pendingFix.Apply(resource)
+
+	contents, err := os.ReadFile(filepath.Join(dir, "kubeaudit-container"))
+	require.NoError(t, err)
+	assert.Contains(t, string(contents), "profile kubeaudit-container")
+
+	containers := k8s.GetContainers(resource)
+	require.NotNil(t, containers[0].SecurityContext)
+	assert.Equal(t, v1.AppArmorProfileTypeLocalhost, containers[0].SecurityContext.AppArmorProfile.Type)
+}
+
+func TestByEmittingLocalhostProfile_EphemeralContainer(t *testing.T) {
+	dir := t.TempDir()
+
+	resource := &k8s.PodV1{Spec: v1.PodSpec{
+		Containers: []v1.Container{{Name: "container"}},
+		EphemeralContainers: []v1.EphemeralContainer{
+			{EphemeralContainerCommon: v1.EphemeralContainerCommon{Name: "debug"}},
+		},
+	}}
+
+	pendingFix := &ByEmittingLocalhostProfile{
+		Container: "debug",
+		Dir:       dir,
+	}
+	pendingFix.Apply(resource)
+
+	ephemeral := k8s.EphemeralContainerByName(resource, "debug")
+	require.NotNil(t, ephemeral.SecurityContext)
+	assert.Equal(t, v1.AppArmorProfileTypeLocalhost, ephemeral.SecurityContext.AppArmorProfile.Type)
+
+	// The regular container named "container" must be left untouched.
+	assert.Nil(t, k8s.GetContainers(resource)[0].SecurityContext)
+}
+
+func TestByEmittingSeccompProfile(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "profiles")
+
+	pendingFix := &ByEmittingSeccompProfile{
+		Container: "container",
+		Dir:       dir,
+	}
+	assert.NotEmpty(t, pendingFix.Plan())
+
+	resource := &k8s.PodV1{Spec: v1.PodSpec{Containers: []v1.Container{{Name: "container"}}}}
+	pendingFix.Apply(resource)
+
+	contents, err := os.ReadFile(filepath.Join(dir, "kubeaudit-container.json"))
+	require.NoError(t, err)
+	assert.Contains(t, string(contents), `"defaultAction": "SCMP_ACT_ERRNO"`)
+
+	containers := k8s.GetContainers(resource)
+	require.NotNil(t, containers[0].SecurityContext)
+	require.NotNil(t, containers[0].SecurityContext.SeccompProfile)
+	assert.Equal(t, v1.SeccompProfileTypeLocalhost, containers[0].SecurityContext.SeccompProfile.Type)
+	assert.Equal(t, "kubeaudit-container.json", *containers[0].SecurityContext.SeccompProfile.LocalhostProfile)
+}
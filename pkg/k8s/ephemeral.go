@@ -0,0 +1,65 @@
+package k8s
+
+import v1 "k8s.io/api/core/v1"
+
+// ContainerOptions configures which of a pod's container lists the container accessors consider.
+type ContainerOptions struct {
+	// IncludeEphemeralContainers causes GetContainersWithOptions to also include the pod's
+	// spec.ephemeralContainers (as added by e.g. `kubectl debug`), converted to a ContainerV1 so existing
+	// auditors can inspect them without any special-casing.
+	IncludeEphemeralContainers bool
+}
+
+// GetContainersWithOptions behaves like GetContainers but, when opts.IncludeEphemeralContainers is set, also
+// appends the pod's ephemeral containers converted to ContainerV1.
+func GetContainersWithOptions(resource Resource, opts ContainerOptions) []*ContainerV1 {
+	containers := GetContainers(resource)
+	if !opts.IncludeEphemeralContainers {
+		return containers
+	}
+	return append(containers, GetEphemeralContainers(resource)...)
+}
+
+// GetEphemeralContainers returns the pod's spec.ephemeralContainers, each converted to a ContainerV1 so
+// existing auditors can inspect their SecurityContext the same way as regular containers. Ephemeral containers
+// are otherwise invisible to auditors, even though `kubectl debug` can use them to bypass the security posture
+// enforced on spec.containers.
+//
+// The returned ContainerV1s are copies, not aliases into the pod spec: they're for reading only. A PendingFix
+// that needs to mutate an ephemeral container must go through EphemeralContainerByName instead.
+func GetEphemeralContainers(resource Resource) []*ContainerV1 {
+	podSpec := GetPodSpec(resource)
+	if podSpec == nil {
+		return nil
+	}
+
+	var containers []*ContainerV1
+	for i := range podSpec.EphemeralContainers {
+		containers = append(containers, ephemeralContainerToContainerV1(&podSpec.EphemeralContainers[i]))
+	}
+	return containers
+}
+
+// ephemeralContainerToContainerV1 converts an EphemeralContainer's common fields to a ContainerV1. This mirrors
+// how the Kubernetes API server itself treats the two types: EphemeralContainerCommon is kept field-for-field
+// identical to Container so that the conversion is lossless. The result is a copy; see GetEphemeralContainers.
+func ephemeralContainerToContainerV1(ephemeral *v1.EphemeralContainer) *ContainerV1 {
+	container := v1.Container(ephemeral.EphemeralContainerCommon)
+	return (*ContainerV1)(&container)
+}
+
+// EphemeralContainerByName returns a pointer to the pod's ephemeral container named name, or nil if no such
+// ephemeral container exists. Unlike GetEphemeralContainers, the returned EphemeralContainerCommon aliases the
+// pod spec, so mutating its SecurityContext (as a PendingFix does) mutates resource itself.
+func EphemeralContainerByName(resource Resource, name string) *v1.EphemeralContainerCommon {
+	podSpec := GetPodSpec(resource)
+	if podSpec == nil {
+		return nil
+	}
+	for i := range podSpec.EphemeralContainers {
+		if podSpec.EphemeralContainers[i].Name == name {
+			return &podSpec.EphemeralContainers[i].EphemeralContainerCommon
+		}
+	}
+	return nil
+}
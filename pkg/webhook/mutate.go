@@ -0,0 +1,56 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/wI2L/jsondiff"
+	admissionv1 "k8s.io/api/admission/v1"
+)
+
+// handleMutate audits the admitted object, applies any PendingFix produced by the configured auditors, and
+// returns the resulting mutation as a JSON Patch.
+func (s *Server) handleMutate(req *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+	resource, err := decodeObject(req)
+	if err != nil {
+		return admissionDenied(fmt.Sprintf("kubeaudit: failed to decode object: %v", err))
+	}
+	if resource == nil {
+		return admissionAllowed()
+	}
+
+	for _, auditor := range s.config.Auditors {
+		results, err := auditor.Audit(resource, nil)
+		if err != nil {
+			return admissionDenied(fmt.Sprintf("kubeaudit: auditor failed: %v", err))
+		}
+		for _, result := range results {
+			if result.PendingFix == nil || !s.auditorEnabledFor(result.Auditor, ModeMutate) {
+				continue
+			}
+			result.PendingFix.Apply(resource)
+		}
+	}
+
+	mutated, err := json.Marshal(resource)
+	if err != nil {
+		return admissionDenied(fmt.Sprintf("kubeaudit: failed to marshal mutated object: %v", err))
+	}
+
+	// AdmissionResponse only supports PatchTypeJSONPatch (RFC 6902 operations), so diff rather than merge.
+	ops, err := jsondiff.CompareJSON(req.Object.Raw, mutated)
+	if err != nil {
+		return admissionDenied(fmt.Sprintf("kubeaudit: failed to compute patch: %v", err))
+	}
+
+	patch, err := json.Marshal(ops)
+	if err != nil {
+		return admissionDenied(fmt.Sprintf("kubeaudit: failed to marshal patch: %v", err))
+	}
+
+	patchType := admissionv1.PatchTypeJSONPatch
+	response := admissionAllowed()
+	response.Patch = patch
+	response.PatchType = &patchType
+	return response
+}
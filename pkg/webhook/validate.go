@@ -0,0 +1,52 @@
+package webhook
+
+import (
+	"fmt"
+
+	admissionv1 "k8s.io/api/admission/v1"
+
+	"github.com/elliotxx/kubeaudit"
+)
+
+// handleValidate audits the admitted object and denies the request if any finding meets or exceeds
+// s.config.MinSeverity. Namespace/label opt-outs (e.g. the apparmor auditor's "allow-disabled-apparmor"
+// override label) are honored because they are applied by the Auditable implementations themselves.
+func (s *Server) handleValidate(req *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+	resource, err := decodeObject(req)
+	if err != nil {
+		return admissionDenied(fmt.Sprintf("kubeaudit: failed to decode object: %v", err))
+	}
+	if resource == nil {
+		return admissionAllowed()
+	}
+
+	var denyResults []*kubeaudit.AuditResult
+	for _, auditor := range s.config.Auditors {
+		results, err := auditor.Audit(resource, nil)
+		if err != nil {
+			return admissionDenied(fmt.Sprintf("kubeaudit: auditor failed: %v", err))
+		}
+		for _, result := range results {
+			if !s.auditorEnabledFor(result.Auditor, ModeValidate) {
+				continue
+			}
+			if result.Severity >= s.config.MinSeverity {
+				denyResults = append(denyResults, result)
+			}
+		}
+	}
+
+	if len(denyResults) == 0 {
+		return admissionAllowed()
+	}
+
+	return admissionDenied(formatDenyReason(denyResults))
+}
+
+func formatDenyReason(results []*kubeaudit.AuditResult) string {
+	reason := "kubeaudit denied this resource:"
+	for _, result := range results {
+		reason += fmt.Sprintf("\n  [%s/%s] %s", result.Auditor, result.Rule, result.Message)
+	}
+	return reason
+}
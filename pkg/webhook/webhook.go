@@ -0,0 +1,172 @@
+// Package webhook runs kubeaudit as an in-cluster admission webhook, reusing the same Auditable
+// implementations as the CLI to validate (and optionally mutate) resources as they are admitted.
+package webhook
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/elliotxx/kubeaudit"
+	"github.com/elliotxx/kubeaudit/config"
+	"github.com/elliotxx/kubeaudit/internal/k8sinternal"
+	"github.com/elliotxx/kubeaudit/pkg/k8s"
+)
+
+// Mode identifies one of the webhook's two admission phases. Values can be combined with bitwise OR, e.g.
+// ModeValidate|ModeMutate for an auditor that participates in both.
+type Mode uint8
+
+const (
+	ModeValidate Mode = 1 << iota
+	ModeMutate
+)
+
+// Has reports whether m includes other.
+func (m Mode) Has(other Mode) bool {
+	return m&other != 0
+}
+
+// Config configures the webhook server.
+type Config struct {
+	// Addr is the address the HTTPS server listens on, e.g. ":8443".
+	Addr string
+	// CertFile and KeyFile point to a TLS certificate/key pair, compatible with those issued by cert-manager.
+	CertFile string
+	KeyFile  string
+	// MinSeverity is the lowest kubeaudit.Severity that causes a validating request to be denied.
+	MinSeverity kubeaudit.Severity
+	// Auditors are the Auditable implementations to run, typically produced by auditors/all.Auditors. Which of
+	// them run at all is handled upstream via config.KubeauditConfig when Auditors is constructed; AuditorModes
+	// further restricts which phase(s) each one participates in once it's enabled.
+	Auditors []kubeaudit.Auditable
+	// AuditorModes restricts which phase(s) each auditor's findings apply in, keyed by the auditor name that
+	// appears in kubeaudit.AuditResult.Auditor (e.g. apparmor.Name). An auditor absent from AuditorModes
+	// participates in both ModeValidate and ModeMutate. This is a webhook-only refinement on top of the
+	// Auditors list: config.KubeauditConfig in this tree has no notion of webhook mode, so it can't be driven
+	// from the config file and must be set directly on Config (e.g. by cmd's webhook flags).
+	AuditorModes map[string]Mode
+	// KubeauditConfig is the configuration Auditors was built from, kept here for callers that need it (e.g.
+	// to rebuild a subset of Auditors, as cmd's --generate-profile wiring does).
+	KubeauditConfig config.KubeauditConfig
+}
+
+// auditorEnabledFor reports whether auditorName's findings should apply in mode, consulting
+// s.config.AuditorModes. An auditor with no entry participates in every mode.
+func (s *Server) auditorEnabledFor(auditorName string, mode Mode) bool {
+	modes, ok := s.config.AuditorModes[auditorName]
+	if !ok {
+		return true
+	}
+	return modes.Has(mode)
+}
+
+// Server is an HTTPS server implementing the Kubernetes AdmissionReview v1 API.
+type Server struct {
+	config Config
+}
+
+// NewServer creates a webhook Server. Handlers are stateless and safe to run behind multiple replicas.
+func NewServer(config Config) *Server {
+	return &Server{config: config}
+}
+
+// Run starts the HTTPS server and blocks until ctx is cancelled or the server returns an error.
+func (s *Server) Run(ctx context.Context) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/validate", s.serve(s.handleValidate))
+	mux.HandleFunc("/mutate", s.serve(s.handleMutate))
+
+	cert, err := tls.LoadX509KeyPair(s.config.CertFile, s.config.KeyFile)
+	if err != nil {
+		return fmt.Errorf("loading webhook TLS certificate: %w", err)
+	}
+
+	server := &http.Server{
+		Addr:      s.config.Addr,
+		Handler:   mux,
+		TLSConfig: &tls.Config{Certificates: []tls.Certificate{cert}},
+	}
+
+	go func() {
+		<-ctx.Done()
+		_ = server.Close()
+	}()
+
+	if err := server.ListenAndServeTLS("", ""); err != nil && err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+// serve decodes the incoming AdmissionReview, invokes handle, and writes back the response.
+func (s *Server) serve(handle func(*admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var review admissionv1.AdmissionReview
+		if err := json.NewDecoder(r.Body).Decode(&review); err != nil {
+			http.Error(w, fmt.Sprintf("decoding admission review: %v", err), http.StatusBadRequest)
+			return
+		}
+		if review.Request == nil {
+			http.Error(w, "admission review is missing request", http.StatusBadRequest)
+			return
+		}
+
+		response := handle(review.Request)
+		response.UID = review.Request.UID
+
+		review.Response = response
+		review.Request = nil
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(review); err != nil {
+			http.Error(w, fmt.Sprintf("encoding admission review: %v", err), http.StatusInternalServerError)
+		}
+	}
+}
+
+func admissionAllowed() *admissionv1.AdmissionResponse {
+	return &admissionv1.AdmissionResponse{Allowed: true}
+}
+
+func admissionDenied(reason string) *admissionv1.AdmissionResponse {
+	return &admissionv1.AdmissionResponse{
+		Allowed: false,
+		Result: &metav1.Status{
+			Status:  metav1.StatusFailure,
+			Message: reason,
+			Reason:  metav1.StatusReasonForbidden,
+		},
+	}
+}
+
+// decodeObject decodes the raw object carried by an AdmissionRequest using the kubeaudit scheme and converts it
+// to a k8s.Resource so that auditors can inspect it the same way they inspect manifests loaded by the CLI.
+// decodeObject returns a nil Resource (with a nil error) for kinds kubeaudit doesn't wrap, so callers should
+// treat a nil result as "allow, nothing to audit" rather than an error.
+func decodeObject(req *admissionv1.AdmissionRequest) (k8s.Resource, error) {
+	obj, err := k8sinternal.DecodeRaw(req.Object.Raw)
+	if err != nil {
+		return nil, err
+	}
+	return toResource(obj), nil
+}
+
+// toResource converts a runtime.Object decoded via the client-go scheme into kubeaudit's own Resource wrapper
+// type, which is what auditors and PendingFixes actually operate on. It returns nil for kinds kubeaudit doesn't
+// have a wrapper for yet.
+func toResource(obj runtime.Object) k8s.Resource {
+	switch o := obj.(type) {
+	case *v1.Pod:
+		return (*k8s.PodV1)(o)
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,85 @@
+package webhook
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	admissionv1 "k8s.io/api/admission/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/elliotxx/kubeaudit"
+	"github.com/elliotxx/kubeaudit/auditors/apparmor"
+)
+
+func unconfinedPodRequest(t *testing.T) *admissionv1.AdmissionRequest {
+	t.Helper()
+	pod := v1.Pod{
+		TypeMeta:   metav1.TypeMeta{Kind: "Pod", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{
+				{
+					Name: "app",
+					SecurityContext: &v1.SecurityContext{
+						AppArmorProfile: &v1.AppArmorProfile{Type: v1.AppArmorProfileTypeUnconfined},
+					},
+				},
+			},
+		},
+	}
+	raw, err := json.Marshal(pod)
+	require.NoError(t, err)
+
+	return &admissionv1.AdmissionRequest{
+		UID:       "00000000-0000-0000-0000-000000000001",
+		Kind:      metav1.GroupVersionKind{Version: "v1", Kind: "Pod"},
+		Object:    runtime.RawExtension{Raw: raw},
+		Namespace: "default",
+	}
+}
+
+func TestHandleValidate_DeniesRealAdmissionRequest(t *testing.T) {
+	server := &Server{config: Config{
+		Auditors:    []kubeaudit.Auditable{apparmor.New(apparmor.Config{})},
+		MinSeverity: kubeaudit.Warning,
+	}}
+
+	response := server.handleValidate(unconfinedPodRequest(t))
+
+	require.NotNil(t, response)
+	assert.False(t, response.Allowed)
+	require.NotNil(t, response.Result)
+	assert.Contains(t, response.Result.Message, apparmor.AppArmorDisabled)
+}
+
+func TestHandleValidate_HonorsAuditorModes(t *testing.T) {
+	server := &Server{config: Config{
+		Auditors:     []kubeaudit.Auditable{apparmor.New(apparmor.Config{})},
+		MinSeverity:  kubeaudit.Warning,
+		AuditorModes: map[string]Mode{apparmor.Name: ModeMutate},
+	}}
+
+	response := server.handleValidate(unconfinedPodRequest(t))
+
+	require.NotNil(t, response)
+	assert.True(t, response.Allowed, "apparmor is mutate-only, so validate should ignore its findings")
+}
+
+func TestHandleMutate_PatchesRealAdmissionRequest(t *testing.T) {
+	server := &Server{config: Config{
+		Auditors: []kubeaudit.Auditable{apparmor.New(apparmor.Config{})},
+	}}
+
+	req := unconfinedPodRequest(t)
+	response := server.handleMutate(req)
+
+	require.NotNil(t, response)
+	assert.True(t, response.Allowed)
+	require.NotNil(t, response.PatchType)
+	assert.Equal(t, admissionv1.PatchTypeJSONPatch, *response.PatchType)
+	assert.NotEmpty(t, response.Patch)
+}